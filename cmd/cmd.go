@@ -0,0 +1,293 @@
+// Package cmd implements cs's subcommand dispatch: list, show, validate,
+// edit, run, and config, plus the `cs <env>` shorthand for `cs run <env>`.
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/heshunme/cc-switcher/internal/config"
+	"github.com/heshunme/cc-switcher/internal/runner"
+)
+
+// Execute dispatches args (typically os.Args[1:]) to the requested
+// subcommand and returns the process exit code. A --profile/--profile=value
+// flag anywhere in args selects a config.<profile>.yaml overlay, the same
+// way $CS_ENV does.
+func Execute(args []string) int {
+	profile, args := extractProfileFlag(args)
+
+	if len(args) == 0 {
+		fmt.Println("Usage: cs <command|environment> [args...]")
+		fmt.Println("Commands: list, show, validate, edit, run, config")
+		fmt.Println("Available environments:")
+		printAvailableEnvironments(profile)
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		return runList(args[1:], profile)
+	case "show":
+		return runShow(args[1:], profile)
+	case "validate":
+		return runValidateCmd(args[1:], profile)
+	case "edit":
+		return runEdit(args[1:])
+	case "run":
+		return runRun(args[1:], profile)
+	case "config":
+		return runConfig(args[1:])
+	default:
+		// `cs <env>` is shorthand for `cs run <env>`.
+		return runRun(args, profile)
+	}
+}
+
+// extractProfileFlag pulls a --profile <value> or --profile=value flag out
+// of args, returning the selected profile (empty if none) and the
+// remaining args in order.
+func extractProfileFlag(args []string) (string, []string) {
+	profile := ""
+	remaining := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--profile" && i+1 < len(args):
+			profile = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--profile="):
+			profile = strings.TrimPrefix(args[i], "--profile=")
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+
+	return profile, remaining
+}
+
+// loadConfig loads the config, selecting a profile overlay if one was given
+// (falling back to $CS_ENV when profile is empty).
+func loadConfig(profile string) (*config.Config, error) {
+	if profile == "" {
+		return config.LoadConfig()
+	}
+	return config.LoadConfigWithProfile(profile)
+}
+
+// runList prints every known environment's name, target, and source file,
+// preceded by the overlay chain (base config plus every conf.d/profile
+// overlay) that was read to build the config.
+func runList(args []string, profile string) int {
+	cfg, err := loadConfig(profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	if len(cfg.LoadedFrom) > 0 {
+		fmt.Printf("Loaded from: %s\n", strings.Join(cfg.LoadedFrom, ", "))
+	}
+
+	for _, name := range sortedNames(cfg.Environments) {
+		env := cfg.Environments[name]
+		fmt.Printf("%s\t%s\t%s\n", name, env.Target, env.Source)
+	}
+
+	return 0
+}
+
+// runShow dumps the fully-resolved environment (inherited/expanded values
+// included) as YAML.
+func runShow(args []string, profile string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: cs show <environment>")
+		return 1
+	}
+
+	cfg, err := loadConfig(profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	env, exists := cfg.Environments[args[0]]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "Environment %q not found.\n", args[0])
+		return 1
+	}
+
+	out, err := yaml.Marshal(env)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to render environment: %v\n", err)
+		return 1
+	}
+
+	fmt.Print(string(out))
+	return 0
+}
+
+// runValidateCmd runs config validation and exits non-zero on any error, so
+// it's suitable for CI.
+func runValidateCmd(args []string, profile string) int {
+	if _, err := loadConfig(profile); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration invalid:\n%v\n", err)
+		return 1
+	}
+
+	fmt.Println("Configuration is valid.")
+	return 0
+}
+
+// runEdit opens $EDITOR on the config file, seeking to the requested
+// environment's definition if one is given.
+func runEdit(args []string) int {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: cs edit [environment]")
+		return 1
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		fmt.Fprintln(os.Stderr, "$EDITOR is not set.")
+		return 1
+	}
+
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to resolve config path: %v\n", err)
+		return 1
+	}
+
+	editorArgs := []string{configPath}
+	if len(args) == 1 {
+		if line, ok := findEnvironmentLine(configPath, args[0]); ok {
+			editorArgs = append(editorArgs, fmt.Sprintf("+%d", line))
+		}
+	}
+
+	editorCmd := exec.Command(editor, editorArgs...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	if err := editorCmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to run editor: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// runConfig implements the `cs config` subcommands. Currently only `path`,
+// which prints the resolved config file path for use in scripts.
+func runConfig(args []string) int {
+	if len(args) != 1 || args[0] != "path" {
+		fmt.Fprintln(os.Stderr, "Usage: cs config path")
+		return 1
+	}
+
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to resolve config path: %v\n", err)
+		return 1
+	}
+
+	fmt.Println(configPath)
+	return 0
+}
+
+// environmentLinePattern matches a top-level `<name>:` key under
+// `environments:` in the config YAML.
+var environmentLinePattern = regexp.MustCompile(`^  (\S+):`)
+
+// findEnvironmentLine returns the 1-indexed line number where name is
+// defined in the config file at path, if found.
+func findEnvironmentLine(path, name string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		if m := environmentLinePattern.FindStringSubmatch(line); m != nil && strings.TrimSuffix(m[1], ":") == name {
+			return i + 1, true
+		}
+	}
+
+	return 0, false
+}
+
+// runRun loads <environment> and runs its target command, forwarding any
+// arguments after <environment> to the target; a leading `--` separator
+// among them is dropped.
+func runRun(args []string, profile string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: cs run <environment> [-- extra args...]")
+		return 1
+	}
+
+	envName := args[0]
+	var extraArgs []string
+	if rest := args[1:]; len(rest) > 0 {
+		if rest[0] == "--" {
+			extraArgs = rest[1:]
+		} else {
+			extraArgs = rest
+		}
+	}
+
+	cfg, err := loadConfig(profile)
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return 1
+	}
+
+	envConfig, exists := cfg.Environments[envName]
+	if !exists {
+		fmt.Printf("Environment '%s' not found.\n", envName)
+		fmt.Println("Available environments:")
+		printAvailableEnvironments(profile)
+		return 1
+	}
+
+	if err := runner.Run(envConfig, extraArgs...); err != nil {
+		log.Printf("Failed to run command: %v", err)
+		return 1
+	}
+
+	return 0
+}
+
+func printAvailableEnvironments(profile string) {
+	cfg, err := loadConfig(profile)
+	if err != nil {
+		fmt.Printf("  (Unable to load config: %v)\n", err)
+		return
+	}
+
+	for _, name := range sortedNames(cfg.Environments) {
+		env := cfg.Environments[name]
+		if env.Source != "" {
+			fmt.Printf("  %s (%s)\n", name, env.Source)
+		} else {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+}
+
+func sortedNames(environments map[string]config.Environment) []string {
+	names := make([]string, 0, len(environments))
+	for name := range environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
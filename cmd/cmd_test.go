@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupTestHome creates a temporary $HOME containing .cs/config.yaml with
+// the given content, and returns that .cs directory plus a cleanup func
+// that restores $HOME.
+func setupTestHome(t *testing.T, configContent string) (configDir string, cleanup func()) {
+	t.Helper()
+
+	tempDir, err := os.MkdirTemp("", "cs-cmd-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+
+	configDir = filepath.Join(tempDir, ".cs")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+
+	return configDir, func() {
+		os.Setenv("HOME", originalHome)
+		os.RemoveAll(tempDir)
+	}
+}
+
+func TestPrintAvailableEnvironments_WithMockConfig(t *testing.T) {
+	_, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+    environment:
+      ENV: "development"
+  prod:
+    target: "echo prod"
+    environment:
+      ENV: "production"
+`)
+	defer cleanup()
+
+	// Test that the function doesn't panic
+	printAvailableEnvironments("")
+}
+
+func TestExecute_List(t *testing.T) {
+	_, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+  prod:
+    target: "echo prod"
+`)
+	defer cleanup()
+
+	if code := Execute([]string{"list"}); code != 0 {
+		t.Errorf("Execute([\"list\"]) = %d, want 0", code)
+	}
+}
+
+// captureStdout redirects os.Stdout for the duration of fn and returns
+// everything written to it.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	original := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	os.Stdout = w
+
+	fn()
+
+	w.Close()
+	os.Stdout = original
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read captured output: %v", err)
+	}
+	return string(out)
+}
+
+func TestExecute_List_PrintsOverlayChain(t *testing.T) {
+	configDir, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+`)
+	defer cleanup()
+
+	confDFile := filepath.Join(configDir, "conf.d", "10-extra.yaml")
+	if err := os.MkdirAll(filepath.Dir(confDFile), 0755); err != nil {
+		t.Fatalf("Failed to create conf.d dir: %v", err)
+	}
+	if err := os.WriteFile(confDFile, []byte(`
+environments:
+  prod:
+    target: "echo prod"
+`), 0644); err != nil {
+		t.Fatalf("Failed to write conf.d overlay: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if code := Execute([]string{"list"}); code != 0 {
+			t.Errorf("Execute([\"list\"]) = %d, want 0", code)
+		}
+	})
+
+	if !strings.Contains(output, "Loaded from: ") {
+		t.Fatalf("Expected output to contain the overlay chain header, got %q", output)
+	}
+	if !strings.Contains(output, filepath.Join(configDir, "config.yaml")) || !strings.Contains(output, confDFile) {
+		t.Errorf("Expected overlay chain to list both the base config and the conf.d overlay, got %q", output)
+	}
+}
+
+func TestExecute_Show(t *testing.T) {
+	_, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+    environment:
+      ENV: "development"
+`)
+	defer cleanup()
+
+	if code := Execute([]string{"show", "dev"}); code != 0 {
+		t.Errorf("Execute([\"show\", \"dev\"]) = %d, want 0", code)
+	}
+
+	if code := Execute([]string{"show", "missing"}); code == 0 {
+		t.Error("Execute([\"show\", \"missing\"]) = 0, want non-zero for unknown environment")
+	}
+}
+
+func TestExecute_Validate(t *testing.T) {
+	_, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+`)
+	defer cleanup()
+
+	if code := Execute([]string{"validate"}); code != 0 {
+		t.Errorf("Execute([\"validate\"]) = %d, want 0", code)
+	}
+}
+
+func TestExecute_Validate_Invalid(t *testing.T) {
+	_, cleanup := setupTestHome(t, `
+environments:
+  broken:
+    environment:
+      FOO: "bar"
+`)
+	defer cleanup()
+
+	if code := Execute([]string{"validate"}); code == 0 {
+		t.Error("Execute([\"validate\"]) = 0, want non-zero for an invalid config")
+	}
+}
+
+func TestExecute_RunShorthand_UnknownEnvironment(t *testing.T) {
+	_, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+`)
+	defer cleanup()
+
+	if code := Execute([]string{"nonexistent"}); code == 0 {
+		t.Error("Execute([\"nonexistent\"]) = 0, want non-zero for unknown environment")
+	}
+}
+
+func TestExecute_Run_EmptyArgs(t *testing.T) {
+	_, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+`)
+	defer cleanup()
+
+	if code := Execute([]string{"run"}); code == 0 {
+		t.Error("Execute([\"run\"]) = 0, want non-zero when no environment is given")
+	}
+}
+
+func TestExecute_ProfileFlag_SelectsOverlay(t *testing.T) {
+	configDir, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+`)
+	defer cleanup()
+
+	profileContent := `
+environments:
+  dev:
+    target: "echo dev-staging"
+`
+	if err := os.WriteFile(filepath.Join(configDir, "config.staging.yaml"), []byte(profileContent), 0644); err != nil {
+		t.Fatalf("Failed to write profile overlay: %v", err)
+	}
+
+	if code := Execute([]string{"--profile", "staging", "show", "dev"}); code != 0 {
+		t.Errorf("Execute with --profile staging = %d, want 0", code)
+	}
+}
+
+func TestExecute_ProfileFlagEquals_SelectsOverlay(t *testing.T) {
+	configDir, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+`)
+	defer cleanup()
+
+	profileContent := `
+environments:
+  dev:
+    target: "echo dev-staging"
+`
+	if err := os.WriteFile(filepath.Join(configDir, "config.staging.yaml"), []byte(profileContent), 0644); err != nil {
+		t.Fatalf("Failed to write profile overlay: %v", err)
+	}
+
+	if code := Execute([]string{"show", "--profile=staging", "dev"}); code != 0 {
+		t.Errorf("Execute with --profile=staging = %d, want 0", code)
+	}
+}
+
+func TestExecute_ConfigPath_PrintsResolvedPath(t *testing.T) {
+	_, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+`)
+	defer cleanup()
+
+	if code := Execute([]string{"config", "path"}); code != 0 {
+		t.Errorf("Execute([\"config\", \"path\"]) = %d, want 0", code)
+	}
+}
+
+func TestExecute_ConfigUnknownSubcommand(t *testing.T) {
+	_, cleanup := setupTestHome(t, `
+environments:
+  dev:
+    target: "echo dev"
+`)
+	defer cleanup()
+
+	if code := Execute([]string{"config", "bogus"}); code == 0 {
+		t.Error("Execute([\"config\", \"bogus\"]) = 0, want non-zero for an unknown config subcommand")
+	}
+}
+
+func TestFindEnvironmentLine(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "cs-cmd-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.yaml")
+	content := "environments:\n  dev:\n    target: \"echo dev\"\n  prod:\n    target: \"echo prod\"\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	line, ok := findEnvironmentLine(configPath, "prod")
+	if !ok {
+		t.Fatal("findEnvironmentLine() did not find 'prod'")
+	}
+	if line != 4 {
+		t.Errorf("Expected 'prod' on line 4, got %d", line)
+	}
+
+	if _, ok := findEnvironmentLine(configPath, "missing"); ok {
+		t.Error("findEnvironmentLine() should not find a nonexistent environment")
+	}
+}
@@ -0,0 +1,431 @@
+// Package config loads, merges, and validates cs's environment
+// configuration: the base config.yaml, conf.d/*.yaml overlays, and the
+// extends/default/required/env semantics layered on top of them.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/heshunme/cc-switcher/internal/expand"
+)
+
+// Environment represents a single environment configuration
+type Environment struct {
+	Target      string            `yaml:"target" required:"true"`
+	Environment map[string]string `yaml:"environment"`
+
+	// Extends names another environment whose Target and Environment this
+	// one inherits. Environment maps are deep merged (this environment's
+	// keys win); Target is inherited only if this environment doesn't set
+	// its own. Resolved and cleared by Config.Validate.
+	Extends string `yaml:"extends,omitempty"`
+
+	// Source is the config file this environment was last defined or
+	// overridden in. It is populated by LoadConfig and never read from YAML.
+	Source string `yaml:"-"`
+}
+
+// Config represents the entire configuration file
+type Config struct {
+	Environments map[string]Environment `yaml:"environments"`
+
+	// LoadedFrom lists every config file that was read to build this
+	// Config, in load order (base config.yaml first, then conf.d overlays,
+	// then the profile overlay if one was selected).
+	LoadedFrom []string `yaml:"-"`
+}
+
+// GetConfigDir returns the user's cs config directory. $CS_CONFIG_DIR, if
+// set, is used as-is. Otherwise the XDG config directory
+// ($XDG_CONFIG_HOME/cs, defaulting to ~/.config/cs) and the legacy ~/.cs are
+// checked in that order, and the first one that already exists on disk
+// wins; if neither exists, the XDG location is returned as the preferred
+// target for a new install.
+func GetConfigDir() (string, error) {
+	if dir := os.Getenv("CS_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	xdgConfigHome := os.Getenv("XDG_CONFIG_HOME")
+	if xdgConfigHome == "" {
+		xdgConfigHome = filepath.Join(homeDir, ".config")
+	}
+	xdgDir := filepath.Join(xdgConfigHome, "cs")
+	legacyDir := filepath.Join(homeDir, ".cs")
+
+	for _, dir := range []string{xdgDir, legacyDir} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+
+	return xdgDir, nil
+}
+
+// GetConfigPath returns the full path to the config file. $CS_CONFIG_FILE,
+// if set, names that file directly; otherwise the path is config.yaml
+// inside GetConfigDir.
+func GetConfigPath() (string, error) {
+	if path := os.Getenv("CS_CONFIG_FILE"); path != "" {
+		return path, nil
+	}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "config.yaml"), nil
+}
+
+// LoadConfig loads the configuration from file or creates a default one. The
+// profile overlay, if any, is selected by $CS_ENV; use LoadConfigWithProfile
+// to select one explicitly instead (e.g. from a --profile flag).
+func LoadConfig() (*Config, error) {
+	return LoadConfigWithProfile(os.Getenv("CS_ENV"))
+}
+
+// LoadConfigWithProfile loads the configuration the same way LoadConfig
+// does, then, if profile is non-empty, overlays config.<profile>.yaml next
+// to the base config file using the same deep-merge semantics as conf.d. A
+// non-empty profile whose file doesn't exist is a hard error rather than a
+// silent fallback to the base config.
+func LoadConfigWithProfile(profile string) (*Config, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if config file exists
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		// Create default config
+		if err := createDefaultConfig(configPath); err != nil {
+			return nil, fmt.Errorf("failed to create default config: %w", err)
+		}
+		fmt.Printf("Created default configuration file: %s\n", configPath)
+		fmt.Println("Please edit the file to add your environment configurations.")
+	}
+
+	config, err := loadConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// conf.d lives next to the config file actually in use, not wherever
+	// GetConfigDir() would otherwise land: with $CS_CONFIG_FILE pointing
+	// somewhere outside the standard config dir, the two can disagree.
+	if err := loadConfDOverlays(config, filepath.Dir(configPath)); err != nil {
+		return nil, err
+	}
+
+	if profile != "" {
+		if err := loadProfileOverlay(config, configPath, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// loadProfileOverlay loads <name>.<profile><ext> next to configPath (so
+// config.<profile>.yaml for config.yaml) and merges it into config using the
+// same deep-merge semantics as conf.d overlays; a missing profile file is an
+// error. It is applied after conf.d so a profile can override anything a
+// conf.d drop-in set.
+func loadProfileOverlay(config *Config, configPath, profile string) error {
+	ext := filepath.Ext(configPath)
+	base := strings.TrimSuffix(filepath.Base(configPath), ext)
+	profilePath := filepath.Join(filepath.Dir(configPath), fmt.Sprintf("%s.%s%s", base, profile, ext))
+
+	if _, err := os.Stat(profilePath); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("profile %q requested but %s does not exist", profile, profilePath)
+		}
+		return fmt.Errorf("failed to stat profile overlay %s: %w", profilePath, err)
+	}
+
+	overlay, err := loadConfigFile(profilePath)
+	if err != nil {
+		return fmt.Errorf("failed to load profile overlay %s: %w", profilePath, err)
+	}
+
+	mergeEnvironments(config.Environments, overlay.Environments)
+	config.LoadedFrom = append(config.LoadedFrom, profilePath)
+
+	return nil
+}
+
+// loadConfigFile reads and parses a single config file, tagging every
+// environment it defines with its Source.
+func loadConfigFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if config.Environments == nil {
+		config.Environments = make(map[string]Environment)
+	}
+
+	for name, env := range config.Environments {
+		env.Source = path
+		config.Environments[name] = env
+	}
+
+	config.LoadedFrom = []string{path}
+
+	return &config, nil
+}
+
+// loadConfDOverlays globs <configDir>/conf.d/*.yaml in lexicographic order
+// and merges each one into config, in place. Environments defined in later
+// files override earlier ones by name; their environment maps are deep
+// merged rather than replaced, so base variables and secrets can be split
+// across files. A malformed overlay file is a hard error.
+func loadConfDOverlays(config *Config, configDir string) error {
+	pattern := filepath.Join(configDir, "conf.d", "*.yaml")
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to glob conf.d overlays: %w", err)
+	}
+	sort.Strings(matches)
+
+	for _, path := range matches {
+		overlay, err := loadConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to load conf.d overlay %s: %w", path, err)
+		}
+
+		mergeEnvironments(config.Environments, overlay.Environments)
+		config.LoadedFrom = append(config.LoadedFrom, path)
+	}
+
+	return nil
+}
+
+// mergeEnvironments merges src into dst in place. An environment present in
+// both is deep merged: a non-empty Target or Extends in src wins, and src's
+// Environment keys are overlaid onto dst's rather than replacing the map
+// wholesale. An environment only present in src is copied as-is.
+func mergeEnvironments(dst, src map[string]Environment) {
+	for name, srcEnv := range src {
+		dstEnv, exists := dst[name]
+		if !exists {
+			dst[name] = srcEnv
+			continue
+		}
+
+		if srcEnv.Target != "" {
+			dstEnv.Target = srcEnv.Target
+		}
+
+		if srcEnv.Extends != "" {
+			dstEnv.Extends = srcEnv.Extends
+		}
+
+		if len(srcEnv.Environment) > 0 {
+			dstEnv.Environment = mergeStringMaps(dstEnv.Environment, srcEnv.Environment)
+		}
+
+		dstEnv.Source = srcEnv.Source
+		dst[name] = dstEnv
+	}
+}
+
+// mergeStringMaps returns a new map containing base's entries overlaid with
+// overlay's, so overlay wins on key collisions. Neither input is mutated.
+func mergeStringMaps(base, overlay map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		merged[k] = v
+	}
+	return merged
+}
+
+// ValidationError collects every problem found while validating a Config,
+// so callers see all of them at once instead of just the first.
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("config validation failed:\n  - %s", strings.Join(e.Errors, "\n  - "))
+}
+
+func (e *ValidationError) add(format string, args ...interface{}) {
+	e.Errors = append(e.Errors, fmt.Sprintf(format, args...))
+}
+
+// Validate resolves `extends` chains, applies struct-tag defaults and
+// required checks (in the spirit of configor), and expands ${VAR}/$VAR
+// references inside each environment's variables. It mutates c.Environments
+// in place with the fully resolved values and returns a *ValidationError
+// listing every problem found, or nil if the config is valid.
+func (c *Config) Validate() error {
+	verr := &ValidationError{}
+
+	for name := range c.Environments {
+		resolved, err := c.resolveExtends(name, nil)
+		if err != nil {
+			verr.add("%s: %v", name, err)
+			continue
+		}
+		c.Environments[name] = resolved
+	}
+
+	for name, env := range c.Environments {
+		if err := applyFieldTags(&env); err != nil {
+			verr.add("%s: %v", name, err)
+		}
+		expandEnvironmentVars(env.Environment)
+		c.Environments[name] = env
+	}
+
+	if len(verr.Errors) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// resolveExtends returns name's Environment with its `extends` chain fully
+// merged in, detecting cycles along the way. chain tracks the names already
+// visited on the current resolution path.
+func (c *Config) resolveExtends(name string, chain []string) (Environment, error) {
+	env, ok := c.Environments[name]
+	if !ok {
+		return Environment{}, fmt.Errorf("extends references unknown environment %q", name)
+	}
+	if env.Extends == "" {
+		return env, nil
+	}
+
+	for _, seen := range chain {
+		if seen == name {
+			return Environment{}, fmt.Errorf("extends cycle detected: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+
+	parent, err := c.resolveExtends(env.Extends, append(chain, name))
+	if err != nil {
+		return Environment{}, err
+	}
+
+	merged := parent
+	if env.Target != "" {
+		merged.Target = env.Target
+	}
+	if len(env.Environment) > 0 {
+		merged.Environment = mergeStringMaps(parent.Environment, env.Environment)
+	}
+	merged.Extends = ""
+	merged.Source = env.Source
+
+	return merged, nil
+}
+
+// applyFieldTags walks the string fields of the struct pointed to by v and
+// honors the `required`, `default`, and `env` struct tags, configor-style:
+// an `env` tag lets a process environment variable override the loaded
+// value, a `default` tag fills in anything still empty, and
+// `required:"true"` fails validation if the field is still empty
+// afterwards. It is generic over any struct so it can be exercised
+// directly in tests even though, today, Environment itself only uses
+// `required` (on Target).
+func applyFieldTags(v interface{}) error {
+	rv := reflect.ValueOf(v).Elem()
+	t := rv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := rv.Field(i)
+		if field.Type.Kind() != reflect.String {
+			continue
+		}
+
+		if envVar := field.Tag.Get("env"); envVar != "" {
+			if val, ok := os.LookupEnv(envVar); ok {
+				fv.SetString(val)
+			}
+		}
+
+		if fv.String() == "" {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				fv.SetString(def)
+			}
+		}
+
+		if field.Tag.Get("required") == "true" && fv.String() == "" {
+			return fmt.Errorf("%s is required", field.Tag.Get("yaml"))
+		}
+	}
+
+	return nil
+}
+
+// expandEnvironmentVars expands ${VAR}/$VAR references and a leading ~
+// inside env's values in place; see expand.EnvironmentVars.
+func expandEnvironmentVars(env map[string]string) {
+	expand.EnvironmentVars(env)
+}
+
+// createDefaultConfig creates a default configuration file
+func createDefaultConfig(configPath string) error {
+	// Ensure config directory exists
+	configDir := filepath.Dir(configPath)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	defaultConfig := `# CS Switcher Configuration File
+# Define your environment configurations here
+
+environments:
+  # GLM environment configuration for Claude Code
+  glm:
+    target: "claude"  # Claude Code command
+    environment:
+      CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC: "1"
+      ANTHROPIC_BASE_URL: "https://open.bigmodel.cn/api/anthropic"
+      ANTHROPIC_AUTH_TOKEN: "your-glm-api-key"
+      ANTHROPIC_MODEL: "glm-4.6"
+      ANTHROPIC_SMALL_FAST_MODEL: "glm-4.5-air"
+      ANTHROPIC_DEFAULT_SONNET_MODEL: "glm-4.6"
+      ANTHROPIC_DEFAULT_OPUS_MODEL: "glm-4.6"
+      ANTHROPIC_DEFAULT_HAIKU_MODEL: "glm-4.5-air"
+      API_TIMEOUT_MS: "3000000"
+
+# Add more environments as needed
+# Example:
+#   myenv:
+#     target: "node server.js"
+#     environment:
+#       PORT: "3000"
+#       NODE_ENV: "production"
+`
+
+	return os.WriteFile(configPath, []byte(defaultConfig), 0644)
+}
@@ -0,0 +1,987 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test helpers
+
+// setupTestConfig creates a temporary config directory and file for testing
+func setupTestConfig(t *testing.T) (configDir, configFile string, cleanup func()) {
+	t.Helper()
+
+	tempDir, err := os.MkdirTemp("", "cs-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+
+	configDir = filepath.Join(tempDir, ".cs")
+	configFile = filepath.Join(configDir, "config.yaml")
+
+	cleanup = func() {
+		os.RemoveAll(tempDir)
+	}
+
+	return configDir, configFile, cleanup
+}
+
+// createTestConfigFile creates a test config file with the given content
+func createTestConfigFile(t *testing.T, configPath string, content string) {
+	t.Helper()
+
+	configDir := filepath.Dir(configPath)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+}
+
+// LoadConfigWithPath is a testable version of LoadConfig that uses a custom
+// config path, including conf.d overlay resolution relative to the config
+// file's directory.
+func LoadConfigWithPath(configPath string) (*Config, error) {
+	return LoadConfigWithPathAndProfile(configPath, "")
+}
+
+// LoadConfigWithPathAndProfile is LoadConfigWithPath plus an explicit
+// profile overlay selection, for testing the config.<profile>.yaml feature
+// without mutating $CS_ENV.
+func LoadConfigWithPathAndProfile(configPath, profile string) (*Config, error) {
+	// Check if config file exists
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		// Create default config
+		if err := createDefaultConfig(configPath); err != nil {
+			return nil, fmt.Errorf("failed to create default config: %w", err)
+		}
+		fmt.Printf("Created default configuration file: %s\n", configPath)
+		fmt.Println("Please edit the file to add your environment configurations.")
+	}
+
+	config, err := loadConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadConfDOverlays(config, filepath.Dir(configPath)); err != nil {
+		return nil, err
+	}
+
+	if profile != "" {
+		if err := loadProfileOverlay(config, configPath, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Configuration Management Tests
+
+func TestCreateDefaultConfig(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	// Test creating default config
+	err := createDefaultConfig(configFile)
+	if err != nil {
+		t.Fatalf("createDefaultConfig() returned error: %v", err)
+	}
+
+	// Verify file was created
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		t.Fatal("Config file was not created")
+	}
+
+	// Verify file contents
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+
+	content := string(data)
+	expectedStrings := []string{
+		"CS Switcher Configuration File",
+		"environments:",
+		"glm:",
+		"target: \"claude\"",
+		"CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Config file doesn't contain expected string: %s", expected)
+		}
+	}
+}
+
+func TestLoadConfigWithPath_ExistingFile(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	// Create a test config file
+	testConfigContent := `
+environments:
+  testenv:
+    target: "echo hello"
+    environment:
+      TEST_VAR: "test_value"
+  another:
+    target: "pwd"
+    environment:
+      PATH: "/custom/path"
+`
+	createTestConfigFile(t, configFile, testConfigContent)
+
+	// Load the config
+	config, err := LoadConfigWithPath(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithPath() returned error: %v", err)
+	}
+
+	// Verify the loaded config
+	if len(config.Environments) != 2 {
+		t.Errorf("Expected 2 environments, got %d", len(config.Environments))
+	}
+
+	testEnv, exists := config.Environments["testenv"]
+	if !exists {
+		t.Fatal("testenv not found in loaded config")
+	}
+
+	if testEnv.Target != "echo hello" {
+		t.Errorf("Expected target 'echo hello', got '%s'", testEnv.Target)
+	}
+
+	if testEnv.Environment["TEST_VAR"] != "test_value" {
+		t.Errorf("Expected TEST_VAR 'test_value', got '%s'", testEnv.Environment["TEST_VAR"])
+	}
+
+	anotherEnv, exists := config.Environments["another"]
+	if !exists {
+		t.Fatal("another environment not found in loaded config")
+	}
+
+	if anotherEnv.Target != "pwd" {
+		t.Errorf("Expected target 'pwd', got '%s'", anotherEnv.Target)
+	}
+}
+
+func TestLoadConfigWithPath_NonExistentFile(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	// Ensure the config file doesn't exist
+	if _, err := os.Stat(configFile); !os.IsNotExist(err) {
+		t.Fatal("Config file already exists")
+	}
+
+	// This should create a default config
+	config, err := LoadConfigWithPath(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithPath() returned error: %v", err)
+	}
+
+	// Verify the config was created
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		t.Fatal("Default config file was not created")
+	}
+
+	// Verify it has at least the glm environment
+	if len(config.Environments) == 0 {
+		t.Fatal("No environments in loaded config")
+	}
+
+	if _, exists := config.Environments["glm"]; !exists {
+		t.Error("Default glm environment not found")
+	}
+}
+
+func TestLoadConfigWithPath_EmptyEnvironments(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	// Create a config file with empty environments
+	testConfigContent := `
+environments: {}
+`
+	createTestConfigFile(t, configFile, testConfigContent)
+
+	config, err := LoadConfigWithPath(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithPath() returned error: %v", err)
+	}
+
+	// Verify environments map is not nil
+	if config.Environments == nil {
+		t.Fatal("Environments map is nil after LoadConfigWithPath")
+	}
+
+	if len(config.Environments) != 0 {
+		t.Errorf("Expected 0 environments, got %d", len(config.Environments))
+	}
+}
+
+func TestLoadConfigWithPath_InvalidYAML(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	// Create a config file with invalid YAML
+	testConfigContent := `
+environments:
+  testenv:
+    target: "echo hello"
+    environment:
+      TEST_VAR: "test_value"
+  invalid_yaml: [unclosed array
+`
+	createTestConfigFile(t, configFile, testConfigContent)
+
+	// This should return an error
+	_, err := LoadConfigWithPath(configFile)
+	if err == nil {
+		t.Fatal("LoadConfigWithPath() should have returned an error for invalid YAML")
+	}
+
+	expectedError := "failed to parse config file"
+	if !strings.Contains(err.Error(), expectedError) {
+		t.Errorf("Expected error containing '%s', got '%s'", expectedError, err.Error())
+	}
+}
+
+// conf.d overlay tests
+
+func TestLoadConfigWithPath_ConfD_AddsNewEnvironment(t *testing.T) {
+	configDir, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  base:
+    target: "echo base"
+    environment:
+      BASE_VAR: "base_value"
+`)
+
+	createTestConfigFile(t, filepath.Join(configDir, "conf.d", "10-extra.yaml"), `
+environments:
+  extra:
+    target: "echo extra"
+    environment:
+      EXTRA_VAR: "extra_value"
+`)
+
+	config, err := LoadConfigWithPath(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithPath() returned error: %v", err)
+	}
+
+	if len(config.Environments) != 2 {
+		t.Fatalf("Expected 2 environments, got %d", len(config.Environments))
+	}
+
+	extra, exists := config.Environments["extra"]
+	if !exists {
+		t.Fatal("extra environment from conf.d was not merged in")
+	}
+
+	if extra.Environment["EXTRA_VAR"] != "extra_value" {
+		t.Errorf("Expected EXTRA_VAR 'extra_value', got '%s'", extra.Environment["EXTRA_VAR"])
+	}
+}
+
+func TestLoadConfigWithPath_ConfD_OrderingAndOverride(t *testing.T) {
+	configDir, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  glm:
+    target: "claude"
+    environment:
+      ANTHROPIC_AUTH_TOKEN: "base-token"
+      ANTHROPIC_MODEL: "glm-4.6"
+`)
+
+	// Files are applied in lexicographic order, so 20-* should win over 10-*.
+	createTestConfigFile(t, filepath.Join(configDir, "conf.d", "10-first.yaml"), `
+environments:
+  glm:
+    environment:
+      ANTHROPIC_AUTH_TOKEN: "first-token"
+`)
+	createTestConfigFile(t, filepath.Join(configDir, "conf.d", "20-second.yaml"), `
+environments:
+  glm:
+    environment:
+      ANTHROPIC_AUTH_TOKEN: "second-token"
+`)
+
+	config, err := LoadConfigWithPath(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithPath() returned error: %v", err)
+	}
+
+	glm := config.Environments["glm"]
+	if glm.Environment["ANTHROPIC_AUTH_TOKEN"] != "second-token" {
+		t.Errorf("Expected later conf.d file to win, got '%s'", glm.Environment["ANTHROPIC_AUTH_TOKEN"])
+	}
+
+	// Deep merge: keys not touched by the overlays must survive untouched.
+	if glm.Environment["ANTHROPIC_MODEL"] != "glm-4.6" {
+		t.Errorf("Expected ANTHROPIC_MODEL to be preserved from base config, got '%s'", glm.Environment["ANTHROPIC_MODEL"])
+	}
+}
+
+func TestLoadConfigWithPath_ConfD_MalformedFileFailsLoudly(t *testing.T) {
+	configDir, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  base:
+    target: "echo base"
+`)
+
+	createTestConfigFile(t, filepath.Join(configDir, "conf.d", "10-broken.yaml"), `
+environments:
+  broken: [unclosed array
+`)
+
+	_, err := LoadConfigWithPath(configFile)
+	if err == nil {
+		t.Fatal("LoadConfigWithPath() should have returned an error for a malformed conf.d file")
+	}
+
+	if !strings.Contains(err.Error(), "conf.d overlay") {
+		t.Errorf("Expected error to mention the failing conf.d overlay, got '%s'", err.Error())
+	}
+}
+
+func TestLoadConfigWithPath_ConfD_OverlayAddsExtends(t *testing.T) {
+	configDir, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  base:
+    target: "echo base"
+    environment:
+      SHARED_VAR: "shared_value"
+  glm:
+    target: "claude"
+`)
+
+	// The base file defines "glm" without extends; a conf.d drop-in adds it.
+	createTestConfigFile(t, filepath.Join(configDir, "conf.d", "10-extends.yaml"), `
+environments:
+  glm:
+    extends: base
+`)
+
+	config, err := LoadConfigWithPath(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithPath() returned error: %v", err)
+	}
+
+	glm := config.Environments["glm"]
+	if glm.Environment["SHARED_VAR"] != "shared_value" {
+		t.Errorf("Expected conf.d-added extends to be resolved, got SHARED_VAR='%s'", glm.Environment["SHARED_VAR"])
+	}
+}
+
+func TestLoadConfigWithPath_LoadedFromListsBaseAndOverlaysInOrder(t *testing.T) {
+	configDir, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  base:
+    target: "echo base"
+`)
+
+	confDFile := filepath.Join(configDir, "conf.d", "10-extra.yaml")
+	createTestConfigFile(t, confDFile, `
+environments:
+  extra:
+    target: "echo extra"
+`)
+
+	config, err := LoadConfigWithPath(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithPath() returned error: %v", err)
+	}
+
+	expected := []string{configFile, confDFile}
+	if len(config.LoadedFrom) != len(expected) {
+		t.Fatalf("Expected LoadedFrom %v, got %v", expected, config.LoadedFrom)
+	}
+	for i, want := range expected {
+		if config.LoadedFrom[i] != want {
+			t.Errorf("LoadedFrom[%d] = %q, want %q", i, config.LoadedFrom[i], want)
+		}
+	}
+}
+
+// Validate() tests: extends, struct tags, ${VAR} expansion
+
+func TestValidate_ExtendsMergesParent(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  base:
+    target: "claude"
+    environment:
+      ANTHROPIC_MODEL: "glm-4.6"
+      ANTHROPIC_AUTH_TOKEN: "base-token"
+  staging:
+    extends: base
+    environment:
+      ANTHROPIC_AUTH_TOKEN: "staging-token"
+`)
+
+	config, err := LoadConfigWithPath(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithPath() returned error: %v", err)
+	}
+
+	staging := config.Environments["staging"]
+	if staging.Target != "claude" {
+		t.Errorf("Expected inherited target 'claude', got '%s'", staging.Target)
+	}
+	if staging.Environment["ANTHROPIC_AUTH_TOKEN"] != "staging-token" {
+		t.Errorf("Expected child override 'staging-token', got '%s'", staging.Environment["ANTHROPIC_AUTH_TOKEN"])
+	}
+	if staging.Environment["ANTHROPIC_MODEL"] != "glm-4.6" {
+		t.Errorf("Expected inherited ANTHROPIC_MODEL, got '%s'", staging.Environment["ANTHROPIC_MODEL"])
+	}
+}
+
+func TestValidate_ExtendsCycleDetected(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  a:
+    target: "echo a"
+    extends: b
+  b:
+    target: "echo b"
+    extends: a
+`)
+
+	_, err := LoadConfigWithPath(configFile)
+	if err == nil {
+		t.Fatal("LoadConfigWithPath() should have returned an error for an extends cycle")
+	}
+	if !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected error to mention the extends cycle, got '%s'", err.Error())
+	}
+}
+
+func TestValidate_RequiredTargetMissing(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  broken:
+    environment:
+      FOO: "bar"
+`)
+
+	_, err := LoadConfigWithPath(configFile)
+	if err == nil {
+		t.Fatal("LoadConfigWithPath() should have returned an error for a missing required target")
+	}
+	if !strings.Contains(err.Error(), "target") {
+		t.Errorf("Expected error to mention the missing target field, got '%s'", err.Error())
+	}
+}
+
+// applyFieldTags tests. No Environment field carries an `env` or `default`
+// tag today (only Target's `required:"true"` is exercised via Validate),
+// so these drive the generic tag-walking logic directly against a local
+// struct to prove the `env` and `default` branches actually work.
+
+type tagTestStruct struct {
+	Name string `yaml:"name" env:"CS_TEST_APPLYFIELDTAGS_NAME"`
+	Mode string `yaml:"mode" default:"auto"`
+	Must string `yaml:"must" required:"true"`
+}
+
+func TestApplyFieldTags_EnvTagOverridesValue(t *testing.T) {
+	os.Setenv("CS_TEST_APPLYFIELDTAGS_NAME", "from-env")
+	defer os.Unsetenv("CS_TEST_APPLYFIELDTAGS_NAME")
+
+	s := tagTestStruct{Name: "from-yaml", Must: "present"}
+	if err := applyFieldTags(&s); err != nil {
+		t.Fatalf("applyFieldTags() returned error: %v", err)
+	}
+	if s.Name != "from-env" {
+		t.Errorf("Expected env tag to override loaded value, got %q", s.Name)
+	}
+}
+
+func TestApplyFieldTags_DefaultFillsEmptyValue(t *testing.T) {
+	clearEnv(t, "CS_TEST_APPLYFIELDTAGS_NAME")
+
+	s := tagTestStruct{Must: "present"}
+	if err := applyFieldTags(&s); err != nil {
+		t.Fatalf("applyFieldTags() returned error: %v", err)
+	}
+	if s.Mode != "auto" {
+		t.Errorf("Expected default tag to fill empty Mode, got %q", s.Mode)
+	}
+}
+
+func TestApplyFieldTags_DefaultDoesNotOverrideExistingValue(t *testing.T) {
+	s := tagTestStruct{Mode: "manual", Must: "present"}
+	if err := applyFieldTags(&s); err != nil {
+		t.Fatalf("applyFieldTags() returned error: %v", err)
+	}
+	if s.Mode != "manual" {
+		t.Errorf("Expected default tag to leave a non-empty Mode alone, got %q", s.Mode)
+	}
+}
+
+func TestApplyFieldTags_RequiredFailsWhenEmpty(t *testing.T) {
+	s := tagTestStruct{}
+	if err := applyFieldTags(&s); err == nil {
+		t.Fatal("applyFieldTags() should have returned an error for a missing required field")
+	}
+}
+
+func TestValidate_ExpandsVarReferences(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	os.Setenv("CS_TEST_EXPAND_HOST", "example.com")
+	defer os.Unsetenv("CS_TEST_EXPAND_HOST")
+
+	createTestConfigFile(t, configFile, `
+environments:
+  expand:
+    target: "echo hi"
+    environment:
+      HOST: "${CS_TEST_EXPAND_HOST}"
+      URL: "https://${HOST}/api"
+`)
+
+	config, err := LoadConfigWithPath(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithPath() returned error: %v", err)
+	}
+
+	expand := config.Environments["expand"]
+	if expand.Environment["HOST"] != "example.com" {
+		t.Errorf("Expected HOST expanded from process environment, got '%s'", expand.Environment["HOST"])
+	}
+	if expand.Environment["URL"] != "https://example.com/api" {
+		t.Errorf("Expected URL to resolve its HOST reference regardless of map ordering, got '%s'", expand.Environment["URL"])
+	}
+}
+
+func TestValidate_ExpandsTildeInEnvironmentValues(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("could not determine home directory: %v", err)
+	}
+
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  expand:
+    target: "echo hi"
+    environment:
+      CLAUDE_CONFIG_DIR: "~/.config/claude"
+`)
+
+	config, err := LoadConfigWithPath(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigWithPath() returned error: %v", err)
+	}
+
+	expected := filepath.Join(home, ".config/claude")
+	expand := config.Environments["expand"]
+	if expand.Environment["CLAUDE_CONFIG_DIR"] != expected {
+		t.Errorf("Expected tilde-expanded CLAUDE_CONFIG_DIR %q, got %q", expected, expand.Environment["CLAUDE_CONFIG_DIR"])
+	}
+}
+
+// Profile overlay (config.<profile>.yaml) tests
+
+func TestLoadConfigWithPathAndProfile_OverlaysMatchingProfile(t *testing.T) {
+	configDir, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  glm:
+    target: "claude"
+    environment:
+      ANTHROPIC_MODEL: "glm-4.6"
+      ANTHROPIC_AUTH_TOKEN: "dev-token"
+`)
+
+	createTestConfigFile(t, filepath.Join(configDir, "config.production.yaml"), `
+environments:
+  glm:
+    environment:
+      ANTHROPIC_AUTH_TOKEN: "prod-token"
+`)
+
+	config, err := LoadConfigWithPathAndProfile(configFile, "production")
+	if err != nil {
+		t.Fatalf("LoadConfigWithPathAndProfile() returned error: %v", err)
+	}
+
+	glm := config.Environments["glm"]
+	if glm.Environment["ANTHROPIC_AUTH_TOKEN"] != "prod-token" {
+		t.Errorf("Expected profile overlay to swap the auth token, got '%s'", glm.Environment["ANTHROPIC_AUTH_TOKEN"])
+	}
+	if glm.Environment["ANTHROPIC_MODEL"] != "glm-4.6" {
+		t.Errorf("Expected the rest of the glm environment to survive untouched, got '%s'", glm.Environment["ANTHROPIC_MODEL"])
+	}
+}
+
+func TestLoadConfigWithPathAndProfile_MissingProfileFileErrors(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  glm:
+    target: "claude"
+`)
+
+	_, err := LoadConfigWithPathAndProfile(configFile, "production")
+	if err == nil {
+		t.Fatal("LoadConfigWithPathAndProfile() should have returned an error for a missing profile file")
+	}
+	if !strings.Contains(err.Error(), "production") {
+		t.Errorf("Expected error to mention the missing profile, got '%s'", err.Error())
+	}
+}
+
+func TestLoadConfigWithPathAndProfile_NoProfileLeavesConfigUnchanged(t *testing.T) {
+	configDir, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	createTestConfigFile(t, configFile, `
+environments:
+  glm:
+    target: "claude"
+    environment:
+      ANTHROPIC_AUTH_TOKEN: "dev-token"
+`)
+
+	createTestConfigFile(t, filepath.Join(configDir, "config.production.yaml"), `
+environments:
+  glm:
+    environment:
+      ANTHROPIC_AUTH_TOKEN: "prod-token"
+`)
+
+	config, err := LoadConfigWithPathAndProfile(configFile, "")
+	if err != nil {
+		t.Fatalf("LoadConfigWithPathAndProfile() returned error: %v", err)
+	}
+
+	if config.Environments["glm"].Environment["ANTHROPIC_AUTH_TOKEN"] != "dev-token" {
+		t.Error("Expected base config to be unaffected when no profile is selected")
+	}
+}
+
+// Config discovery tests
+
+// withEnv sets key to value for the duration of the test, restoring
+// whatever was there before (including "unset") on cleanup.
+func withEnv(t *testing.T, key, value string) {
+	t.Helper()
+
+	original, had := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(key, original)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// clearEnv unsets key for the duration of the test, restoring it on
+// cleanup if it was previously set.
+func clearEnv(t *testing.T, key string) {
+	t.Helper()
+
+	original, had := os.LookupEnv(key)
+	os.Unsetenv(key)
+	t.Cleanup(func() {
+		if had {
+			os.Setenv(key, original)
+		}
+	})
+}
+
+func TestGetConfigPath_CSConfigFileOverride(t *testing.T) {
+	withEnv(t, "CS_CONFIG_FILE", "/tmp/explicit-cs-config.yaml")
+
+	path, err := GetConfigPath()
+	if err != nil {
+		t.Fatalf("GetConfigPath() returned error: %v", err)
+	}
+	if path != "/tmp/explicit-cs-config.yaml" {
+		t.Errorf("GetConfigPath() = %q, want %q", path, "/tmp/explicit-cs-config.yaml")
+	}
+}
+
+func TestLoadConfigWithProfile_ConfDResolvesAgainstCSConfigFileNotCSConfigDir(t *testing.T) {
+	configFileDir, err := os.MkdirTemp("", "cs-config-file-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(configFileDir) })
+
+	otherConfigDir, err := os.MkdirTemp("", "cs-config-dir-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(otherConfigDir) })
+
+	configFile := filepath.Join(configFileDir, "myconfig.yaml")
+	createTestConfigFile(t, configFile, `
+environments:
+  base:
+    target: "echo base"
+`)
+
+	// A conf.d overlay next to the CS_CONFIG_FILE path.
+	createTestConfigFile(t, filepath.Join(configFileDir, "conf.d", "10-extra.yaml"), `
+environments:
+  extra:
+    target: "echo extra"
+`)
+
+	// A decoy conf.d overlay under the unrelated CS_CONFIG_DIR, which must
+	// NOT be consulted once CS_CONFIG_FILE is set.
+	createTestConfigFile(t, filepath.Join(otherConfigDir, "conf.d", "10-decoy.yaml"), `
+environments:
+  decoy:
+    target: "echo decoy"
+`)
+
+	withEnv(t, "CS_CONFIG_FILE", configFile)
+	withEnv(t, "CS_CONFIG_DIR", otherConfigDir)
+
+	config, err := LoadConfigWithProfile("")
+	if err != nil {
+		t.Fatalf("LoadConfigWithProfile() returned error: %v", err)
+	}
+
+	if _, exists := config.Environments["extra"]; !exists {
+		t.Error("Expected conf.d overlay next to CS_CONFIG_FILE to be applied")
+	}
+	if _, exists := config.Environments["decoy"]; exists {
+		t.Error("conf.d overlay under unrelated CS_CONFIG_DIR should not have been consulted")
+	}
+}
+
+func TestGetConfigDir_CSConfigDirOverride(t *testing.T) {
+	clearEnv(t, "CS_CONFIG_FILE")
+	withEnv(t, "CS_CONFIG_DIR", "/tmp/explicit-cs-config-dir")
+
+	dir, err := GetConfigDir()
+	if err != nil {
+		t.Fatalf("GetConfigDir() returned error: %v", err)
+	}
+	if dir != "/tmp/explicit-cs-config-dir" {
+		t.Errorf("GetConfigDir() = %q, want %q", dir, "/tmp/explicit-cs-config-dir")
+	}
+}
+
+func TestGetConfigDir_UsesXDGConfigHomeWhenItExists(t *testing.T) {
+	clearEnv(t, "CS_CONFIG_FILE")
+	clearEnv(t, "CS_CONFIG_DIR")
+
+	home, err := os.MkdirTemp("", "cs-home-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp home: %v", err)
+	}
+	defer os.RemoveAll(home)
+
+	xdgHome, err := os.MkdirTemp("", "cs-xdg-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp XDG_CONFIG_HOME: %v", err)
+	}
+	defer os.RemoveAll(xdgHome)
+
+	want := filepath.Join(xdgHome, "cs")
+	if err := os.MkdirAll(want, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", want, err)
+	}
+
+	withEnv(t, "HOME", home)
+	withEnv(t, "XDG_CONFIG_HOME", xdgHome)
+
+	dir, err := GetConfigDir()
+	if err != nil {
+		t.Fatalf("GetConfigDir() returned error: %v", err)
+	}
+	if dir != want {
+		t.Errorf("GetConfigDir() = %q, want %q", dir, want)
+	}
+}
+
+func TestGetConfigDir_FallsBackToDotConfigCsWhenXDGEnvUnset(t *testing.T) {
+	clearEnv(t, "CS_CONFIG_FILE")
+	clearEnv(t, "CS_CONFIG_DIR")
+	clearEnv(t, "XDG_CONFIG_HOME")
+
+	home, err := os.MkdirTemp("", "cs-home-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp home: %v", err)
+	}
+	defer os.RemoveAll(home)
+
+	want := filepath.Join(home, ".config", "cs")
+	if err := os.MkdirAll(want, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", want, err)
+	}
+
+	withEnv(t, "HOME", home)
+
+	dir, err := GetConfigDir()
+	if err != nil {
+		t.Fatalf("GetConfigDir() returned error: %v", err)
+	}
+	if dir != want {
+		t.Errorf("GetConfigDir() = %q, want %q", dir, want)
+	}
+}
+
+func TestGetConfigDir_FallsBackToLegacyDotCsWhenNoXDGDirExists(t *testing.T) {
+	clearEnv(t, "CS_CONFIG_FILE")
+	clearEnv(t, "CS_CONFIG_DIR")
+	clearEnv(t, "XDG_CONFIG_HOME")
+
+	home, err := os.MkdirTemp("", "cs-home-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp home: %v", err)
+	}
+	defer os.RemoveAll(home)
+
+	legacyDir := filepath.Join(home, ".cs")
+	if err := os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", legacyDir, err)
+	}
+
+	withEnv(t, "HOME", home)
+
+	dir, err := GetConfigDir()
+	if err != nil {
+		t.Fatalf("GetConfigDir() returned error: %v", err)
+	}
+	if dir != legacyDir {
+		t.Errorf("GetConfigDir() = %q, want legacy dir %q", dir, legacyDir)
+	}
+}
+
+func TestGetConfigDir_DefaultsToXDGLocationWhenNothingExists(t *testing.T) {
+	clearEnv(t, "CS_CONFIG_FILE")
+	clearEnv(t, "CS_CONFIG_DIR")
+	clearEnv(t, "XDG_CONFIG_HOME")
+
+	home, err := os.MkdirTemp("", "cs-home-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp home: %v", err)
+	}
+	defer os.RemoveAll(home)
+
+	withEnv(t, "HOME", home)
+
+	want := filepath.Join(home, ".config", "cs")
+
+	dir, err := GetConfigDir()
+	if err != nil {
+		t.Fatalf("GetConfigDir() returned error: %v", err)
+	}
+	if dir != want {
+		t.Errorf("GetConfigDir() = %q, want preferred XDG target %q", dir, want)
+	}
+}
+
+// Edge Case Tests
+
+func TestConcurrency_SimultaneousConfigAccess(t *testing.T) {
+	_, configFile, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	testConfigContent := `
+environments:
+  concurrent1:
+    target: "echo test1"
+    environment:
+      VAR1: "value1"
+  concurrent2:
+    target: "echo test2"
+    environment:
+      VAR2: "value2"
+`
+	createTestConfigFile(t, configFile, testConfigContent)
+
+	// Run multiple LoadConfig calls concurrently
+	const numGoroutines = 10
+	done := make(chan bool, numGoroutines)
+	errors := make(chan error, numGoroutines)
+
+	for i := 0; i < numGoroutines; i++ {
+		go func() {
+			config, err := LoadConfigWithPath(configFile)
+			if err != nil {
+				errors <- err
+				done <- true
+				return
+			}
+
+			// Verify the config is valid
+			if len(config.Environments) != 2 {
+				errors <- fmt.Errorf("expected 2 environments, got %d", len(config.Environments))
+				done <- true
+				return
+			}
+
+			errors <- nil
+			done <- true
+		}()
+	}
+
+	// Wait for all goroutines to complete
+	for i := 0; i < numGoroutines; i++ {
+		<-done
+	}
+
+	// Check for any errors
+	close(errors)
+	for err := range errors {
+		if err != nil {
+			t.Errorf("Concurrent load error: %v", err)
+		}
+	}
+}
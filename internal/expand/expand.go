@@ -0,0 +1,63 @@
+// Package expand expands ~ and ${VAR}/$VAR references shared by config and
+// runner: config expands Environment values once at load time, runner
+// expands them again (plus Target's tokens) at exec time so a raw
+// Environment built without going through config.Validate is still safe
+// to run.
+package expand
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// VarRefPattern matches ${VAR} and $VAR references.
+var VarRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// VarRefs replaces ${VAR}/$VAR references in value, resolving against env
+// first and falling back to the process environment. Unresolved references
+// are left as-is.
+func VarRefs(value string, env map[string]string) string {
+	return VarRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(ref, "${"), "$"), "}")
+		if v, ok := env[name]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		return ref
+	})
+}
+
+// Home expands a leading ~ or ~/ in path to the current user's home
+// directory. Paths that don't start with ~ are returned unchanged.
+func Home(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	if path == "~" {
+		return home
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// EnvironmentVars expands ${VAR}/$VAR references and a leading ~ inside
+// env's values in place, resolving against the environment's own keys and,
+// failing that, the process environment. It runs two passes so a value can
+// reference a sibling key regardless of which of the two is defined first
+// in the map. Unresolved references are left as-is.
+func EnvironmentVars(env map[string]string) {
+	for pass := 0; pass < 2; pass++ {
+		for key, value := range env {
+			env[key] = Home(VarRefs(value, env))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package expand
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVarRefs_ResolvesFromEnvMapThenProcessEnv(t *testing.T) {
+	os.Setenv("CS_TEST_EXPAND_PROCESS_VAR", "from-process")
+	defer os.Unsetenv("CS_TEST_EXPAND_PROCESS_VAR")
+
+	env := map[string]string{"LOCAL_VAR": "from-map"}
+
+	got := VarRefs("${LOCAL_VAR}/$CS_TEST_EXPAND_PROCESS_VAR", env)
+	want := "from-map/from-process"
+	if got != want {
+		t.Errorf("VarRefs() = %q, want %q", got, want)
+	}
+}
+
+func TestVarRefs_LeavesUnresolvedReferencesAsIs(t *testing.T) {
+	got := VarRefs("$CS_TEST_EXPAND_UNDEFINED", nil)
+	if got != "$CS_TEST_EXPAND_UNDEFINED" {
+		t.Errorf("VarRefs() = %q, want unchanged reference", got)
+	}
+}
+
+func TestHome_ExpandsLeadingTilde(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("could not determine home directory: %v", err)
+	}
+
+	if got, want := Home("~"), home; got != want {
+		t.Errorf("Home(\"~\") = %q, want %q", got, want)
+	}
+
+	want := filepath.Join(home, ".config/claude")
+	if got := Home("~/.config/claude"); got != want {
+		t.Errorf("Home() = %q, want %q", got, want)
+	}
+}
+
+func TestHome_LeavesNonTildePathsUnchanged(t *testing.T) {
+	if got := Home("/etc/claude"); got != "/etc/claude" {
+		t.Errorf("Home() = %q, want unchanged path", got)
+	}
+}
+
+func TestEnvironmentVars_ExpandsSiblingReferencesAndTilde(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("could not determine home directory: %v", err)
+	}
+
+	env := map[string]string{
+		"HOST":              "example.com",
+		"URL":               "https://${HOST}/api",
+		"CLAUDE_CONFIG_DIR": "~/.config/claude",
+	}
+
+	EnvironmentVars(env)
+
+	if env["URL"] != "https://example.com/api" {
+		t.Errorf("Expected URL to resolve its HOST reference, got %q", env["URL"])
+	}
+	if want := filepath.Join(home, ".config/claude"); env["CLAUDE_CONFIG_DIR"] != want {
+		t.Errorf("Expected CLAUDE_CONFIG_DIR tilde-expanded to %q, got %q", want, env["CLAUDE_CONFIG_DIR"])
+	}
+}
@@ -0,0 +1,84 @@
+// Package runner executes a resolved environment's target command with its
+// configured environment variables.
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/google/shlex"
+
+	"github.com/heshunme/cc-switcher/internal/config"
+	"github.com/heshunme/cc-switcher/internal/expand"
+)
+
+// Run executes the target command with the specified environment variables.
+// extraArgs, if given, are appended verbatim after the parsed target (e.g.
+// trailing arguments from `cs run <env> -- --foo`).
+func Run(envConfig config.Environment, extraArgs ...string) error {
+	if envConfig.Target == "" {
+		return fmt.Errorf("target command is empty")
+	}
+
+	// envConfig.Environment is visible to itself (siblings can reference
+	// each other) and to the target command's own tilde/${VAR} expansion.
+	env := make(map[string]string, len(envConfig.Environment))
+	for k, v := range envConfig.Environment {
+		env[k] = v
+	}
+	expand.EnvironmentVars(env)
+
+	// Tokenize the target command with real shell quoting rules instead of
+	// a naive whitespace split, so quoted args and paths with spaces work.
+	parts, err := buildCommandParts(envConfig.Target, env)
+	if err != nil {
+		return err
+	}
+	parts = append(parts, extraArgs...)
+
+	command := parts[0]
+	args := parts[1:]
+
+	// Create the command
+	cmd := exec.Command(command, args...)
+
+	// Set up environment variables
+	if len(env) > 0 {
+		// Start with current environment
+		cmdEnv := os.Environ()
+
+		// Add or override with our environment variables
+		for key, value := range env {
+			cmdEnv = append(cmdEnv, fmt.Sprintf("%s=%s", key, value))
+		}
+
+		cmd.Env = cmdEnv
+	}
+
+	// Set up standard I/O to connect to the current terminal
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Run the command
+	return cmd.Run()
+}
+
+// buildCommandParts tokenizes target using POSIX shell quoting rules, then
+// expands ~ and ${VAR}/$VAR references in each resulting token against env.
+func buildCommandParts(target string, env map[string]string) ([]string, error) {
+	parts, err := shlex.Split(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target command %q: %w", target, err)
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("invalid target command")
+	}
+
+	for i, part := range parts {
+		parts[i] = expand.Home(expand.VarRefs(part, env))
+	}
+
+	return parts, nil
+}
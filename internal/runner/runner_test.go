@@ -0,0 +1,172 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/heshunme/cc-switcher/internal/config"
+)
+
+// Command Execution Tests
+
+func TestRun_EmptyTarget(t *testing.T) {
+	env := config.Environment{
+		Target:      "",
+		Environment: map[string]string{},
+	}
+
+	err := Run(env)
+	if err == nil {
+		t.Fatal("Run() should have returned an error for empty target")
+	}
+
+	expectedError := "target command is empty"
+	if !strings.Contains(err.Error(), expectedError) {
+		t.Errorf("Expected error containing '%s', got '%s'", expectedError, err.Error())
+	}
+}
+
+func TestRun_WhitespaceOnlyTarget(t *testing.T) {
+	env := config.Environment{
+		Target:      "   ",
+		Environment: map[string]string{},
+	}
+
+	err := Run(env)
+	if err == nil {
+		t.Fatal("Run() should have returned an error for whitespace-only target")
+	}
+
+	expectedError := "invalid target command"
+	if !strings.Contains(err.Error(), expectedError) {
+		t.Errorf("Expected error containing '%s', got '%s'", expectedError, err.Error())
+	}
+}
+
+func TestRun_SimpleCommand(t *testing.T) {
+	env := config.Environment{
+		Target:      "echo hello world",
+		Environment: map[string]string{},
+	}
+
+	err := Run(env)
+	if err != nil {
+		t.Logf("Run() returned error (command might not exist): %v", err)
+	}
+}
+
+func TestRun_ExtraArgsAppended(t *testing.T) {
+	env := config.Environment{
+		Target:      "echo hello",
+		Environment: map[string]string{},
+	}
+
+	err := Run(env, "world", "--flag")
+	if err != nil {
+		t.Logf("Run() returned error (command might not exist): %v", err)
+	}
+}
+
+func TestRun_ExpandsEnvironmentValues(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("could not determine home directory: %v", err)
+	}
+
+	outFile, err := os.CreateTemp("", "cs-run-env-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	outFile.Close()
+	defer os.Remove(outFile.Name())
+
+	// A raw Environment built without going through config.Validate (which
+	// would have already expanded these) must still be expanded by Run
+	// itself before the child process sees it.
+	env := config.Environment{
+		Target: fmt.Sprintf("sh -c 'printenv MY_HOME_PATH > %s'", outFile.Name()),
+		Environment: map[string]string{
+			"MY_HOME_PATH": "~/somewhere",
+		},
+	}
+
+	if err := Run(env); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	got, err := os.ReadFile(outFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	want := filepath.Join(home, "somewhere") + "\n"
+	if string(got) != want {
+		t.Errorf("Expected child process to see expanded MY_HOME_PATH=%q, got %q", want, string(got))
+	}
+}
+
+// buildCommandParts tests
+
+func TestBuildCommandParts_QuotedArgs(t *testing.T) {
+	parts, err := buildCommandParts(`claude --system-prompt "hello world" --model=foo`, nil)
+	if err != nil {
+		t.Fatalf("buildCommandParts() returned error: %v", err)
+	}
+
+	expected := []string{"claude", "--system-prompt", "hello world", "--model=foo"}
+	if len(parts) != len(expected) {
+		t.Fatalf("Expected %d parts, got %d: %v", len(expected), len(parts), parts)
+	}
+	for i, want := range expected {
+		if parts[i] != want {
+			t.Errorf("part %d: expected %q, got %q", i, want, parts[i])
+		}
+	}
+}
+
+func TestBuildCommandParts_TildeExpansion(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("could not determine home directory: %v", err)
+	}
+
+	parts, err := buildCommandParts("~/bin/claude ~/.config/claude.json", nil)
+	if err != nil {
+		t.Fatalf("buildCommandParts() returned error: %v", err)
+	}
+
+	expectedCommand := filepath.Join(home, "bin/claude")
+	if parts[0] != expectedCommand {
+		t.Errorf("Expected tilde-expanded command %q, got %q", expectedCommand, parts[0])
+	}
+
+	expectedArg := filepath.Join(home, ".config/claude.json")
+	if parts[1] != expectedArg {
+		t.Errorf("Expected tilde-expanded arg %q, got %q", expectedArg, parts[1])
+	}
+}
+
+func TestBuildCommandParts_EnvVarExpansionFromEnvironmentKeys(t *testing.T) {
+	env := map[string]string{
+		"BASE_URL": "https://example.com",
+	}
+
+	parts, err := buildCommandParts(`curl ${BASE_URL}/health`, env)
+	if err != nil {
+		t.Fatalf("buildCommandParts() returned error: %v", err)
+	}
+
+	if parts[1] != "https://example.com/health" {
+		t.Errorf("Expected expanded URL arg, got %q", parts[1])
+	}
+}
+
+func TestBuildCommandParts_UnterminatedQuoteIsRejected(t *testing.T) {
+	_, err := buildCommandParts(`claude --system-prompt "unterminated`, nil)
+	if err == nil {
+		t.Fatal("buildCommandParts() should have returned an error for an unterminated quote")
+	}
+}